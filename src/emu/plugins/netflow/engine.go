@@ -10,7 +10,9 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"math/bits"
 	"math/rand"
+	"sort"
 	"unicode/utf8"
 )
 
@@ -246,6 +248,7 @@ type HistogramEngine struct {
 	par           *HistogramEngineParams // params as provided by the caller
 	distributions []uint32               // distribution slice
 	generator     *NonUniformRandGen     // non uniform random generator per distribution
+	lastSize      uint16                 // size in bytes actually written on the last call to Update
 
 }
 
@@ -267,7 +270,10 @@ func (o *HistogramEngine) buildDistributionSlice(entries []HistogramEntry) {
 	}
 }
 
-// Update implements the Update function of FieldEngineIF.
+// Update implements the Update function of FieldEngineIF. An entry is allowed to
+// produce fewer bytes than par.size, which happens when it wraps a variable length
+// sub-engine (e.g. a TemplateHistogramEntry around a StringEngine or TemplateEngine);
+// the actual number of bytes written is kept and can be read back with LastWriteSize.
 func (o *HistogramEngine) Update(b []byte) error {
 	if len(b) < int(o.par.size) {
 		return fmt.Errorf("Provided slice is shorter that the size of the variable to write, want at least %v, have %v.\n", o.par.size, len(b))
@@ -278,13 +284,10 @@ func (o *HistogramEngine) Update(b []byte) error {
 	if err != nil {
 		return err
 	}
-	if len(newValueBytes) < int(o.par.size) {
-		return fmt.Errorf("New value length is shorter that it should be, want %v, have %v.\n", o.par.size, len(newValueBytes))
-	}
-	copiedSize := copy(b[:o.par.size], newValueBytes[:o.par.size])
-	if copiedSize != int(o.par.size) {
-		return fmt.Errorf("Didn't copy the right amount to the buffer, want %v have %v.\n", o.par.size, copiedSize)
+	if len(newValueBytes) > int(o.par.size) {
+		return fmt.Errorf("New value length is longer that it should be, want at most %v, have %v.\n", o.par.size, len(newValueBytes))
 	}
+	o.lastSize = uint16(copy(b, newValueBytes))
 	return nil
 }
 
@@ -293,6 +296,13 @@ func (o *HistogramEngine) GetOffset() uint16 {
 	return o.par.offset
 }
 
+// LastWriteSize implements the variableSizeEngine interface, allowing a HistogramEngine
+// whose entries are themselves variable length (e.g. TemplateHistogramEntry) to be
+// nested inside a TemplateEngine without its worst case GetSize() being taken literally.
+func (o *HistogramEngine) LastWriteSize() uint16 {
+	return o.lastSize
+}
+
 // GetSize implements the GetSize function of FieldEngineIF.
 func (o *HistogramEngine) GetSize() uint16 {
 	return o.par.size
@@ -455,4 +465,469 @@ func (o *HistogramRuneListEntry) GetValue() (b []byte, err error) {
 // GetProb returns the probability for this entry to be picked in the histogram engine.
 func (o *HistogramRuneListEntry) GetProb() uint32 {
 	return o.prob
-}
\ No newline at end of file
+}
+
+/* ------------------------------------------------------------------------------
+						HistogramLogLinearEntry
+--------------------------------------------------------------------------------*/
+// logLinearBin is a single bin of a Circonus-style log-linear histogram.
+// The bin is identified by a base-10 exponent e and a two-digit significand m
+// in [10, 99], and it spans the half-open interval [m * 10^(e-1), (m+1) * 10^(e-1)).
+type logLinearBin struct {
+	e      int8   // base-10 exponent of the bin
+	m      uint8  // two-digit significand of the bin, in [10, 99]
+	weight uint64 // weight (count) of this bin
+}
+
+// LogLinearBinWeight is a single (exponent, significand, weight) tuple used to
+// seed a HistogramLogLinearEntry with a pre-computed bin table.
+type LogLinearBinWeight struct {
+	E      int8   // base-10 exponent of the bin
+	M      uint8  // two-digit significand of the bin, in [10, 99]
+	Weight uint64 // weight (count) of this bin
+}
+
+// HistogramLogLinearEntryParams is a struct of parameters for the HistogramLogLinearEntry.
+type HistogramLogLinearEntryParams struct {
+	prob    uint32               // probability of this entry
+	size    uint16               // size in bytes of the value to write, either 4 or 8
+	isFloat bool                 // if true, the value is encoded as an IEEE-754 float instead of a big-endian integer
+	bins    []LogLinearBinWeight // initial weighted bin table, can be empty and populated later with AddBin/AddFloatSample/AddIntSample
+}
+
+// HistogramLogLinearEntry represents a Circonus-style log-linear histogram which can be used as
+// an entry for the HistogramEngine. This entry can be picked with probability prob.
+// If the entry is picked, a bin is chosen proportionally to its weight and a value is sampled
+// uniformly inside that bin's interval. This allows replaying real captured distributions
+// (packet sizes, flow durations, byte counts) with ~5% relative error across many orders of
+// magnitude, which uniform/range entries can't approximate.
+type HistogramLogLinearEntry struct {
+	bins    []logLinearBin // bin table, weight per (e, m) pair
+	total   uint64         // sum of the weights of all bins, cached for fast sampling
+	size    uint16         // size in bytes of the value to write, either 4 or 8
+	isFloat bool           // if true, the value is encoded as an IEEE-754 float instead of a big-endian integer
+	prob    uint32         // probability of this entry
+}
+
+// NewHistogramLogLinearEntry creates a new HistogramLogLinearEntry from the
+// HistogramLogLinearEntryParams provided.
+func NewHistogramLogLinearEntry(params *HistogramLogLinearEntryParams) (o *HistogramLogLinearEntry, err error) {
+	if params.size != 4 && params.size != 8 {
+		return nil, fmt.Errorf("Invalid size %v. Size should be {4, 8}.\n", params.size)
+	}
+	o = new(HistogramLogLinearEntry)
+	o.size = params.size
+	o.isFloat = params.isFloat
+	o.prob = params.prob
+	for _, w := range params.bins {
+		if err = o.AddBin(w.E, w.M, w.Weight); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// AddBin inserts or increments the weight of the bin identified by (e, m) by weight.
+func (o *HistogramLogLinearEntry) AddBin(e int8, m uint8, weight uint64) error {
+	if m < 10 || m > 99 {
+		return fmt.Errorf("Significand %v is out of range, should be in [10, 99].\n", m)
+	}
+	for i := range o.bins {
+		if o.bins[i].e == e && o.bins[i].m == m {
+			o.bins[i].weight += weight
+			o.total += weight
+			return nil
+		}
+	}
+	o.bins = append(o.bins, logLinearBin{e: e, m: m, weight: weight})
+	o.total += weight
+	return nil
+}
+
+// quantizeLogLinear finds the (e, m) bin that a positive sample v falls into, via log10.
+func quantizeLogLinear(v float64) (e int8, m uint8, err error) {
+	if v <= 0 {
+		return 0, 0, fmt.Errorf("Sample %v must be strictly positive for a log-linear histogram.\n", v)
+	}
+	exp := int(math.Floor(math.Log10(v)))
+	scale := math.Pow10(exp - 1)
+	mf := math.Floor(v / scale)
+	// guard against floating point rounding pushing the significand just outside [10, 100)
+	if mf >= 100 {
+		exp++
+		mf = math.Floor(v / math.Pow10(exp-1))
+	} else if mf < 10 {
+		exp--
+		mf = math.Floor(v / math.Pow10(exp-1))
+	}
+	return int8(exp), uint8(mf), nil
+}
+
+// AddFloatSample quantizes v to its (e, m) bin and increments that bin's weight by one,
+// growing the bin table on the fly. Use this to load a real captured distribution.
+func (o *HistogramLogLinearEntry) AddFloatSample(v float64) error {
+	e, m, err := quantizeLogLinear(v)
+	if err != nil {
+		return err
+	}
+	return o.AddBin(e, m, 1)
+}
+
+// AddIntSample quantizes v to its (e, m) bin and increments that bin's weight by one.
+// It is a convenience wrapper around AddFloatSample for integer-valued samples.
+func (o *HistogramLogLinearEntry) AddIntSample(v uint64) error {
+	return o.AddFloatSample(float64(v))
+}
+
+// logLinearBinBounds returns the half-open interval [lo, hi) spanned by the (e, m) bin.
+func logLinearBinBounds(e int8, m uint8) (lo, hi float64) {
+	scale := math.Pow10(int(e) - 1)
+	lo = float64(m) * scale
+	hi = float64(m+1) * scale
+	return lo, hi
+}
+
+// GetValue picks a bin proportionally to its weight, samples a value uniformly inside
+// the bin's interval and puts it on the byte buffer.
+func (o *HistogramLogLinearEntry) GetValue() (b []byte, err error) {
+	if len(o.bins) == 0 || o.total == 0 {
+		return nil, errors.New("No bins with non zero weight in HistogramLogLinearEntry.\n")
+	}
+	target := rand.Uint64() % o.total
+	var cum uint64
+	chosen := &o.bins[len(o.bins)-1]
+	for i := range o.bins {
+		cum += o.bins[i].weight
+		if target < cum {
+			chosen = &o.bins[i]
+			break
+		}
+	}
+	lo, hi := logLinearBinBounds(chosen.e, chosen.m)
+	v := lo + rand.Float64()*(hi-lo)
+	b = make([]byte, o.size)
+	switch o.size {
+	case 4:
+		if o.isFloat {
+			binary.BigEndian.PutUint32(b, math.Float32bits(float32(v)))
+		} else {
+			binary.BigEndian.PutUint32(b, uint32(v))
+		}
+	case 8:
+		if o.isFloat {
+			binary.BigEndian.PutUint64(b, math.Float64bits(v))
+		} else {
+			binary.BigEndian.PutUint64(b, uint64(v))
+		}
+	default:
+		return nil, fmt.Errorf("Size should be 4 or 8 for HistogramLogLinearEntry, got %v.\n", o.size)
+	}
+	return b, nil
+}
+
+// GetProb returns the probability for this entry to be picked in the histogram engine.
+func (o *HistogramLogLinearEntry) GetProb() uint32 {
+	return o.prob
+}
+
+/* ------------------------------------------------------------------------------
+						HistogramHDREntry
+--------------------------------------------------------------------------------*/
+// hdrBin is a single HDR-style sub-bucket. Values in [2^k, 2^(k+1)) are split into
+// an equal number of linear sub-buckets, and subIndex identifies which one this is.
+type hdrBin struct {
+	k        uint8  // power-of-two exponent, values live in [2^k, 2^(k+1))
+	subIndex uint32 // sub-bucket index within the power-of-two range, in [0, subBucketCount)
+	weight   uint64 // weight (count) of this sub-bucket
+}
+
+// HDRBinWeight is a single (power-of-two, sub-bucket, weight) tuple used to seed a
+// HistogramHDREntry with a pre-computed bin table.
+type HDRBinWeight struct {
+	K        uint8  // power-of-two exponent, values live in [2^K, 2^(K+1))
+	SubIndex uint32 // sub-bucket index within the power-of-two range
+	Weight   uint64 // weight (count) of this sub-bucket
+}
+
+// HistogramHDREntryParams is a struct of parameters for the HistogramHDREntry.
+type HistogramHDREntryParams struct {
+	prob         uint32         // probability of this entry
+	size         uint16         // size in bytes of the value to write, either 4 or 8
+	lowestValue  uint64         // lowest value trackable by the histogram
+	highestValue uint64         // highest value trackable by the histogram
+	sigDigits    uint8          // number of significant digits, each power of two is split in 2^sigDigits linear sub-buckets
+	bins         []HDRBinWeight // initial weighted bin table, can be empty and populated later with AddBin/AddSample
+}
+
+// HistogramHDREntry represents an HDR-style histogram which can be used as an entry for the
+// HistogramEngine. This entry can be picked with probability prob. If the entry is picked,
+// a sub-bucket is chosen proportionally to its weight and a value is sampled uniformly
+// inside that sub-bucket's interval. Splitting every power of two into 2^sigDigits equal
+// linear sub-buckets gives constant relative precision with integer-friendly math, which is
+// convenient for things like microsecond latencies or byte counters.
+type HistogramHDREntry struct {
+	bins           []hdrBin // bin table, weight per (k, subIndex) pair
+	total          uint64   // sum of the weights of all bins, cached for fast sampling
+	size           uint16   // size in bytes of the value to write, either 4 or 8
+	lowestValue    uint64   // lowest value trackable by the histogram
+	highestValue   uint64   // highest value trackable by the histogram
+	sigDigits      uint8    // number of significant digits
+	subBucketCount uint32   // 2^sigDigits, the number of linear sub-buckets per power of two
+	prob           uint32   // probability of this entry
+}
+
+// NewHistogramHDREntry creates a new HistogramHDREntry from the HistogramHDREntryParams provided.
+func NewHistogramHDREntry(params *HistogramHDREntryParams) (o *HistogramHDREntry, err error) {
+	if params.size != 4 && params.size != 8 {
+		return nil, fmt.Errorf("Invalid size %v. Size should be {4, 8}.\n", params.size)
+	}
+	if params.lowestValue == 0 || params.lowestValue > params.highestValue {
+		return nil, fmt.Errorf("Lowest value %v must be strictly positive and not bigger than highest value %v.\n", params.lowestValue, params.highestValue)
+	}
+	if params.sigDigits == 0 || params.sigDigits > 20 {
+		return nil, fmt.Errorf("Significant digits %v should be in [1, 20].\n", params.sigDigits)
+	}
+	// Every power-of-two bucket a value can quantize into must span at least
+	// subBucketCount integers, or subBucketCount linear sub-buckets can't be told apart
+	// (the division below would truncate to a zero-width interval). Since the bucket a
+	// value falls into only gets wider as the value grows, requiring this to hold for
+	// lowestValue is enough to guarantee it for every value in [lowestValue, highestValue].
+	if params.lowestValue < uint64(1)<<params.sigDigits {
+		return nil, fmt.Errorf("Lowest value %v is too small for %v significant digits, it must be at least %v.\n", params.lowestValue, params.sigDigits, uint64(1)<<params.sigDigits)
+	}
+	o = new(HistogramHDREntry)
+	o.size = params.size
+	o.lowestValue = params.lowestValue
+	o.highestValue = params.highestValue
+	o.sigDigits = params.sigDigits
+	o.subBucketCount = uint32(1) << params.sigDigits
+	o.prob = params.prob
+	for _, w := range params.bins {
+		if err = o.AddBin(w.K, w.SubIndex, w.Weight); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// AddBin inserts or increments the weight of the sub-bucket identified by (k, subIndex) by weight.
+func (o *HistogramHDREntry) AddBin(k uint8, subIndex uint32, weight uint64) error {
+	if subIndex >= o.subBucketCount {
+		return fmt.Errorf("Sub bucket index %v is out of range, should be smaller than %v.\n", subIndex, o.subBucketCount)
+	}
+	for i := range o.bins {
+		if o.bins[i].k == k && o.bins[i].subIndex == subIndex {
+			o.bins[i].weight += weight
+			o.total += weight
+			return nil
+		}
+	}
+	o.bins = append(o.bins, hdrBin{k: k, subIndex: subIndex, weight: weight})
+	o.total += weight
+	return nil
+}
+
+// quantizeHDR finds the (k, subIndex) sub-bucket that a sample v falls into. v must be
+// within [lowestValue, highestValue], the range the histogram was configured to track.
+func (o *HistogramHDREntry) quantizeHDR(v uint64) (k uint8, subIndex uint32, err error) {
+	if v < o.lowestValue || v > o.highestValue {
+		return 0, 0, fmt.Errorf("Sample %v is outside the trackable range [%v, %v].\n", v, o.lowestValue, o.highestValue)
+	}
+	k = uint8(bits.Len64(v) - 1)
+	low := uint64(1) << k
+	span := low // high = 1 << (k+1) = 2*low, so span = high - low = low
+	// (v - low) * subBucketCount can overflow a uint64 for large k, so it is kept on 128 bits.
+	prodHi, prodLo := bits.Mul64(v-low, uint64(o.subBucketCount))
+	q, _ := bits.Div64(prodHi, prodLo, span)
+	subIndex = uint32(q)
+	if subIndex >= o.subBucketCount {
+		subIndex = o.subBucketCount - 1
+	}
+	return k, subIndex, nil
+}
+
+// AddSample quantizes v to its (k, subIndex) sub-bucket and increments that sub-bucket's
+// weight by one, growing the bin table on the fly. Use this to load recorded traffic.
+func (o *HistogramHDREntry) AddSample(v uint64) error {
+	k, subIndex, err := o.quantizeHDR(v)
+	if err != nil {
+		return err
+	}
+	return o.AddBin(k, subIndex, 1)
+}
+
+// hdrBinBounds returns the half-open interval [lo, hi) spanned by the (k, subIndex) sub-bucket.
+// For the last sub-bucket of k = 63 the upper bound is 2^64, which wraps hi to 0.
+func hdrBinBounds(k uint8, subIndex uint32, subBucketCount uint32) (lo, hi uint64) {
+	low := uint64(1) << k
+	span := low
+	// subIndex * span can overflow a uint64 for large k, so the products are kept on 128 bits.
+	prodHi, prodLo := bits.Mul64(uint64(subIndex), span)
+	offLo, _ := bits.Div64(prodHi, prodLo, uint64(subBucketCount))
+	prodHi, prodLo = bits.Mul64(uint64(subIndex)+1, span)
+	offHi, _ := bits.Div64(prodHi, prodLo, uint64(subBucketCount))
+	lo = low + offLo
+	hi = low + offHi
+	return lo, hi
+}
+
+// GetValue picks a sub-bucket proportionally to its weight, samples a value uniformly
+// inside the sub-bucket's interval and puts it on the byte buffer.
+func (o *HistogramHDREntry) GetValue() (b []byte, err error) {
+	if len(o.bins) == 0 || o.total == 0 {
+		return nil, errors.New("No bins with non zero weight in HistogramHDREntry.\n")
+	}
+	target := rand.Uint64() % o.total
+	var cum uint64
+	chosen := &o.bins[len(o.bins)-1]
+	for i := range o.bins {
+		cum += o.bins[i].weight
+		if target < cum {
+			chosen = &o.bins[i]
+			break
+		}
+	}
+	lo, hi := hdrBinBounds(chosen.k, chosen.subIndex, o.subBucketCount)
+	v := lo
+	if hi > lo {
+		v = lo + rand.Uint64()%(hi-lo)
+	}
+	b = make([]byte, o.size)
+	switch o.size {
+	case 4:
+		binary.BigEndian.PutUint32(b, uint32(v))
+	case 8:
+		binary.BigEndian.PutUint64(b, v)
+	default:
+		return nil, fmt.Errorf("Size should be 4 or 8 for HistogramHDREntry, got %v.\n", o.size)
+	}
+	return b, nil
+}
+
+// GetProb returns the probability for this entry to be picked in the histogram engine.
+func (o *HistogramHDREntry) GetProb() uint32 {
+	return o.prob
+}
+
+/* ------------------------------------------------------------------------------
+						HistogramCDFEntry
+--------------------------------------------------------------------------------*/
+// CDFBucket is a single pre-bucketed CDF point, as exported by Prometheus style
+// histograms: every value up to and including UpperBound has been observed
+// CumulativeCount times so far. The last bucket may use math.Inf(1) as UpperBound,
+// in which case HistogramCDFEntryParams.maxValue is used to clamp it.
+type CDFBucket struct {
+	UpperBound      float64 // upper bound of this bucket, exclusive of the previous bucket's bound
+	CumulativeCount uint64  // cumulative count of observations up to and including UpperBound
+}
+
+// HistogramCDFEntryParams is a struct of parameters for the HistogramCDFEntry.
+type HistogramCDFEntryParams struct {
+	prob     uint32      // probability of this entry
+	size     uint16      // size in bytes of the value to write, one of {4, 8}
+	isFloat  bool        // if true, the value is encoded as an IEEE-754 float instead of a big-endian integer
+	buckets  []CDFBucket // sorted, pre-bucketed CDF, e.g. a Prometheus histogram_quantile export
+	maxValue float64     // clamps a +Inf upper bound on the last bucket to this value
+}
+
+// HistogramCDFEntry represents a Prometheus-style pre-bucketed CDF which can be used as
+// an entry for the HistogramEngine. This entry can be picked with probability prob.
+// If the entry is picked, a target count is chosen uniformly in [0, totalCount), the
+// bucket whose cumulative range contains it is found by binary search, and a value is
+// linearly interpolated inside that bucket. This lets users import Prometheus
+// histogram_quantile-style bucket exports directly as a NetFlow field source.
+type HistogramCDFEntry struct {
+	buckets    []CDFBucket // sorted, pre-bucketed CDF
+	totalCount uint64      // cumulative count of the last bucket, cached for fast sampling
+	size       uint16      // size in bytes of the value to write, one of {4, 8}
+	isFloat    bool        // if true, the value is encoded as an IEEE-754 float instead of a big-endian integer
+	maxValue   float64     // clamps a +Inf upper bound on the last bucket to this value
+	prob       uint32      // probability of this entry
+}
+
+// NewHistogramCDFEntry creates a new HistogramCDFEntry from the HistogramCDFEntryParams
+// provided, validating that bounds are strictly increasing and counts are non-decreasing.
+func NewHistogramCDFEntry(params *HistogramCDFEntryParams) (o *HistogramCDFEntry, err error) {
+	if params.size != 4 && params.size != 8 {
+		return nil, fmt.Errorf("Invalid size %v. Size should be {4, 8}.\n", params.size)
+	}
+	if len(params.buckets) == 0 {
+		return nil, errors.New("Can't create a HistogramCDFEntry with an empty list of buckets.\n")
+	}
+	prevBound := math.Inf(-1)
+	var prevCount uint64
+	for i, bucket := range params.buckets {
+		if bucket.UpperBound <= prevBound {
+			return nil, fmt.Errorf("Bucket %v upper bound %v is not strictly increasing over the previous bound %v.\n", i, bucket.UpperBound, prevBound)
+		}
+		if bucket.CumulativeCount < prevCount {
+			return nil, fmt.Errorf("Bucket %v cumulative count %v is smaller than the previous bucket's count %v.\n", i, bucket.CumulativeCount, prevCount)
+		}
+		if math.IsInf(bucket.UpperBound, 1) && params.maxValue <= prevBound {
+			return nil, fmt.Errorf("maxValue %v must be bigger than the previous bucket's bound %v to clamp the +Inf bucket.\n", params.maxValue, prevBound)
+		}
+		prevBound = bucket.UpperBound
+		prevCount = bucket.CumulativeCount
+	}
+	o = new(HistogramCDFEntry)
+	o.buckets = params.buckets
+	o.totalCount = prevCount
+	o.size = params.size
+	o.isFloat = params.isFloat
+	o.maxValue = params.maxValue
+	o.prob = params.prob
+	return o, nil
+}
+
+// GetValue picks a target count uniformly in [0, totalCount), binary searches for the
+// bucket whose cumulative range contains it, linearly interpolates a value inside that
+// bucket and puts it on the byte buffer.
+func (o *HistogramCDFEntry) GetValue() (b []byte, err error) {
+	if o.totalCount == 0 {
+		return nil, errors.New("No observations in HistogramCDFEntry.\n")
+	}
+	// target < totalCount == buckets[len(buckets)-1].CumulativeCount, so the search
+	// always finds a bucket and never falls off the end of the slice.
+	target := rand.Uint64() % o.totalCount
+	i := sort.Search(len(o.buckets), func(i int) bool {
+		return o.buckets[i].CumulativeCount > target
+	})
+	var lo float64
+	var cumLo uint64
+	if i > 0 {
+		lo = o.buckets[i-1].UpperBound
+		cumLo = o.buckets[i-1].CumulativeCount
+	}
+	hi := o.buckets[i].UpperBound
+	if math.IsInf(hi, 1) {
+		hi = o.maxValue
+	}
+	cumHi := o.buckets[i].CumulativeCount
+	v := lo
+	if cumHi > cumLo {
+		v = lo + (hi-lo)*float64(target-cumLo)/float64(cumHi-cumLo)
+	}
+	b = make([]byte, o.size)
+	switch o.size {
+	case 4:
+		if o.isFloat {
+			binary.BigEndian.PutUint32(b, math.Float32bits(float32(v)))
+		} else {
+			binary.BigEndian.PutUint32(b, uint32(v))
+		}
+	case 8:
+		if o.isFloat {
+			binary.BigEndian.PutUint64(b, math.Float64bits(v))
+		} else {
+			binary.BigEndian.PutUint64(b, uint64(v))
+		}
+	default:
+		return nil, fmt.Errorf("Size should be 4 or 8 for HistogramCDFEntry, got %v.\n", o.size)
+	}
+	return b, nil
+}
+
+// GetProb returns the probability for this entry to be picked in the histogram engine.
+func (o *HistogramCDFEntry) GetProb() uint32 {
+	return o.prob
+}
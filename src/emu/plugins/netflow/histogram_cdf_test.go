@@ -0,0 +1,94 @@
+// Copyright (c) 2020 Cisco Systems and/or its affiliates.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package netflow
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestNewHistogramCDFEntryValidatesBounds(t *testing.T) {
+	cases := []struct {
+		name    string
+		buckets []CDFBucket
+		maxVal  float64
+	}{
+		{
+			name: "non increasing upper bound",
+			buckets: []CDFBucket{
+				{UpperBound: 10, CumulativeCount: 1},
+				{UpperBound: 10, CumulativeCount: 2},
+			},
+		},
+		{
+			name: "decreasing cumulative count",
+			buckets: []CDFBucket{
+				{UpperBound: 10, CumulativeCount: 5},
+				{UpperBound: 20, CumulativeCount: 2},
+			},
+		},
+		{
+			name: "+Inf bucket without a big enough maxValue",
+			buckets: []CDFBucket{
+				{UpperBound: 10, CumulativeCount: 1},
+				{UpperBound: math.Inf(1), CumulativeCount: 2},
+			},
+			maxVal: 5,
+		},
+	}
+	for _, c := range cases {
+		_, err := NewHistogramCDFEntry(&HistogramCDFEntryParams{prob: 1, size: 8, buckets: c.buckets, maxValue: c.maxVal})
+		if err == nil {
+			t.Errorf("%s: expected an error", c.name)
+		}
+	}
+}
+
+func TestHistogramCDFEntryGetValueSingleObservation(t *testing.T) {
+	o, err := NewHistogramCDFEntry(&HistogramCDFEntryParams{
+		prob: 1, size: 8, isFloat: true,
+		buckets: []CDFBucket{{UpperBound: 100, CumulativeCount: 1}},
+	})
+	if err != nil {
+		t.Fatalf("NewHistogramCDFEntry failed: %v", err)
+	}
+	// With a single observation, target is always 0 and falls at the very start of the
+	// only bucket, so the interpolated value is deterministic.
+	b, err := o.GetValue()
+	if err != nil {
+		t.Fatalf("GetValue failed: %v", err)
+	}
+	v := math.Float64frombits(binary.BigEndian.Uint64(b))
+	if v != 0 {
+		t.Errorf("GetValue() = %v, want 0", v)
+	}
+}
+
+func TestHistogramCDFEntryGetValueWithinBounds(t *testing.T) {
+	o, err := NewHistogramCDFEntry(&HistogramCDFEntryParams{
+		prob: 1, size: 8, isFloat: true,
+		buckets: []CDFBucket{
+			{UpperBound: 10, CumulativeCount: 5},
+			{UpperBound: 100, CumulativeCount: 20},
+			{UpperBound: math.Inf(1), CumulativeCount: 30},
+		},
+		maxValue: 1000,
+	})
+	if err != nil {
+		t.Fatalf("NewHistogramCDFEntry failed: %v", err)
+	}
+	for i := 0; i < 1000; i++ {
+		b, err := o.GetValue()
+		if err != nil {
+			t.Fatalf("GetValue failed: %v", err)
+		}
+		v := math.Float64frombits(binary.BigEndian.Uint64(b))
+		if v < 0 || v > 1000 {
+			t.Fatalf("GetValue() = %v, want a value in [0, 1000]", v)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2020 Cisco Systems and/or its affiliates.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package netflow
+
+import "testing"
+
+func TestNewHistogramHDREntryRejectsLowestValueTooSmall(t *testing.T) {
+	_, err := NewHistogramHDREntry(&HistogramHDREntryParams{
+		prob: 1, size: 8, lowestValue: 4, highestValue: 1000, sigDigits: 3, // subBucketCount = 8 > lowestValue
+	})
+	if err == nil {
+		t.Fatal("expected an error when lowestValue is smaller than the sub bucket count")
+	}
+}
+
+func TestHDRSubBucketsAreNotDegenerate(t *testing.T) {
+	o, err := NewHistogramHDREntry(&HistogramHDREntryParams{
+		prob: 1, size: 8, lowestValue: 8, highestValue: 1000, sigDigits: 3, // subBucketCount = 8
+	})
+	if err != nil {
+		t.Fatalf("NewHistogramHDREntry failed: %v", err)
+	}
+	for k := uint8(3); k <= 9; k++ {
+		seen := make(map[uint64]bool)
+		for subIndex := uint32(0); subIndex < o.subBucketCount; subIndex++ {
+			lo, hi := hdrBinBounds(k, subIndex, o.subBucketCount)
+			if lo >= hi {
+				t.Errorf("k=%v subIndex=%v: degenerate bucket [%v, %v)", k, subIndex, lo, hi)
+			}
+			if seen[lo] {
+				t.Errorf("k=%v subIndex=%v: bucket lower bound %v collides with a previous sub-bucket", k, subIndex, lo)
+			}
+			seen[lo] = true
+		}
+	}
+}
+
+func TestQuantizeHDRRejectsOutOfRange(t *testing.T) {
+	o, err := NewHistogramHDREntry(&HistogramHDREntryParams{
+		prob: 1, size: 8, lowestValue: 8, highestValue: 1000, sigDigits: 3,
+	})
+	if err != nil {
+		t.Fatalf("NewHistogramHDREntry failed: %v", err)
+	}
+	if err := o.AddSample(1); err == nil {
+		t.Error("AddSample below lowestValue should have returned an error")
+	}
+	if err := o.AddSample(10000); err == nil {
+		t.Error("AddSample above highestValue should have returned an error")
+	}
+	if err := o.AddSample(500); err != nil {
+		t.Errorf("AddSample within range should not fail: %v", err)
+	}
+}
+
+func TestQuantizeHDRRoundTrip(t *testing.T) {
+	o, err := NewHistogramHDREntry(&HistogramHDREntryParams{
+		prob: 1, size: 8, lowestValue: 8, highestValue: 1000, sigDigits: 3,
+	})
+	if err != nil {
+		t.Fatalf("NewHistogramHDREntry failed: %v", err)
+	}
+	for _, v := range []uint64{8, 15, 16, 100, 999, 1000} {
+		k, subIndex, err := o.quantizeHDR(v)
+		if err != nil {
+			t.Fatalf("quantizeHDR(%v) returned unexpected error: %v", v, err)
+		}
+		lo, hi := hdrBinBounds(k, subIndex, o.subBucketCount)
+		if v < lo || v >= hi {
+			t.Errorf("bounds [%v, %v) for quantizeHDR(%v) = (k=%v, subIndex=%v) don't contain the sample", lo, hi, v, k, subIndex)
+		}
+	}
+}
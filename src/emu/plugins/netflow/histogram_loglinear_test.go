@@ -0,0 +1,58 @@
+// Copyright (c) 2020 Cisco Systems and/or its affiliates.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package netflow
+
+import "testing"
+
+func TestQuantizeLogLinearBoundaries(t *testing.T) {
+	cases := []struct {
+		v float64
+		e int8
+		m uint8
+	}{
+		{v: 10, e: 1, m: 10},
+		{v: 99, e: 1, m: 99},
+		{v: 99.999, e: 1, m: 99},
+		{v: 100, e: 2, m: 10},
+		{v: 999, e: 2, m: 99},
+		{v: 1000, e: 3, m: 10},
+		{v: 1, e: 0, m: 10},
+	}
+	for _, c := range cases {
+		e, m, err := quantizeLogLinear(c.v)
+		if err != nil {
+			t.Fatalf("quantizeLogLinear(%v) returned unexpected error: %v", c.v, err)
+		}
+		if e != c.e || m != c.m {
+			t.Errorf("quantizeLogLinear(%v) = (%v, %v), want (%v, %v)", c.v, e, m, c.e, c.m)
+		}
+		lo, hi := logLinearBinBounds(e, m)
+		if c.v < lo || c.v >= hi {
+			t.Errorf("bounds [%v, %v) for quantizeLogLinear(%v) = (%v, %v) don't contain the sample", lo, hi, c.v, e, m)
+		}
+	}
+}
+
+func TestQuantizeLogLinearRejectsNonPositive(t *testing.T) {
+	for _, v := range []float64{0, -1} {
+		if _, _, err := quantizeLogLinear(v); err == nil {
+			t.Errorf("quantizeLogLinear(%v) should have returned an error", v)
+		}
+	}
+}
+
+func TestHistogramLogLinearEntryAddBinValidatesSignificand(t *testing.T) {
+	entry, err := NewHistogramLogLinearEntry(&HistogramLogLinearEntryParams{prob: 1, size: 4})
+	if err != nil {
+		t.Fatalf("NewHistogramLogLinearEntry failed: %v", err)
+	}
+	if err := entry.AddBin(1, 5, 1); err == nil {
+		t.Error("AddBin with an out of range significand should have returned an error")
+	}
+	if err := entry.AddBin(1, 10, 1); err != nil {
+		t.Errorf("AddBin with a valid significand should not fail: %v", err)
+	}
+}
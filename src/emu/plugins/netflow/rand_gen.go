@@ -0,0 +1,93 @@
+// Copyright (c) 2020 Cisco Systems and/or its affiliates.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package netflow
+
+import (
+	"errors"
+	"math/rand"
+)
+
+// NonUniformRandGen is a fast non uniform pseudo random generator used by HistogramEngine
+// to pick an entry's index proportionally to its probability. It is built once, at
+// construction, with Walker/Vose's alias method, so that Generate afterwards costs two
+// random draws and no search, regardless of how many entries the table holds.
+type NonUniformRandGen struct {
+	prob  []float64 // per entry probability threshold, in [0, 1]
+	alias []int     // per entry alias index to fall back to
+}
+
+// NewNonUniformRandGen builds the alias tables for the Vose alias method out of the
+// provided per entry weights. Weights don't need to sum to anything in particular,
+// they are normalized internally so their average is 1.0.
+func NewNonUniformRandGen(weights []uint32) (o *NonUniformRandGen, err error) {
+	n := len(weights)
+	if n == 0 {
+		return nil, errors.New("Can't create a NonUniformRandGen with an empty list of weights.\n")
+	}
+	var sum float64
+	for _, w := range weights {
+		sum += float64(w)
+	}
+	if sum == 0 {
+		return nil, errors.New("Sum of weights can't be zero in NonUniformRandGen.\n")
+	}
+
+	scaled := make([]float64, n)
+	for i, w := range weights {
+		scaled[i] = float64(w) * float64(n) / sum
+	}
+
+	o = new(NonUniformRandGen)
+	o.prob = make([]float64, n)
+	o.alias = make([]int, n)
+
+	small := make([]int, 0, n)
+	large := make([]int, 0, n)
+	for i, p := range scaled {
+		if p < 1 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+
+	for len(small) > 0 && len(large) > 0 {
+		s := small[len(small)-1]
+		small = small[:len(small)-1]
+		l := large[len(large)-1]
+		large = large[:len(large)-1]
+
+		o.prob[s] = scaled[s]
+		o.alias[s] = l
+
+		scaled[l] = scaled[l] + scaled[s] - 1
+		if scaled[l] < 1 {
+			small = append(small, l)
+		} else {
+			large = append(large, l)
+		}
+	}
+	// What's left over is only there because of floating point rounding, not because
+	// it is genuinely ambiguous: treat every remaining entry as certain to be picked.
+	for _, l := range large {
+		o.prob[l] = 1
+	}
+	for _, s := range small {
+		o.prob[s] = 1
+	}
+
+	return o, nil
+}
+
+// Generate picks an index in [0, n) proportionally to the weights the generator was
+// built with. It costs exactly two random draws, no search.
+func (o *NonUniformRandGen) Generate() int {
+	i := rand.Intn(len(o.prob))
+	if rand.Float64() < o.prob[i] {
+		return i
+	}
+	return o.alias[i]
+}
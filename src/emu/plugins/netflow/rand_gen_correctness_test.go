@@ -0,0 +1,59 @@
+// Copyright (c) 2020 Cisco Systems and/or its affiliates.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package netflow
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNonUniformRandGenNeverPicksZeroWeightEntry(t *testing.T) {
+	gen, err := NewNonUniformRandGen([]uint32{5, 0, 3, 0, 2})
+	if err != nil {
+		t.Fatalf("NewNonUniformRandGen failed: %v", err)
+	}
+	for i := 0; i < 10000; i++ {
+		if idx := gen.Generate(); idx == 1 || idx == 3 {
+			t.Fatalf("Generate() returned %v, a zero weight entry should never be picked", idx)
+		}
+	}
+}
+
+func TestNonUniformRandGenTracksWeights(t *testing.T) {
+	weights := []uint32{1, 2, 7}
+	gen, err := NewNonUniformRandGen(weights)
+	if err != nil {
+		t.Fatalf("NewNonUniformRandGen failed: %v", err)
+	}
+	const n = 200000
+	counts := make([]int, len(weights))
+	for i := 0; i < n; i++ {
+		counts[gen.Generate()]++
+	}
+	var total uint32
+	for _, w := range weights {
+		total += w
+	}
+	for i, w := range weights {
+		want := float64(w) / float64(total)
+		got := float64(counts[i]) / float64(n)
+		if math.Abs(got-want) > 0.01 {
+			t.Errorf("entry %v: empirical frequency %v, want close to %v", i, got, want)
+		}
+	}
+}
+
+func TestNewNonUniformRandGenRejectsAllZeroWeights(t *testing.T) {
+	if _, err := NewNonUniformRandGen([]uint32{0, 0, 0}); err == nil {
+		t.Fatal("expected an error when every weight is zero")
+	}
+}
+
+func TestNewNonUniformRandGenRejectsEmptyWeights(t *testing.T) {
+	if _, err := NewNonUniformRandGen(nil); err == nil {
+		t.Fatal("expected an error when the weight list is empty")
+	}
+}
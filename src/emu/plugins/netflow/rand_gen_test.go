@@ -0,0 +1,75 @@
+// Copyright (c) 2020 Cisco Systems and/or its affiliates.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package netflow
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// legacyNonUniformRandGen is the linear-scan generator HistogramEngine used to rely on.
+// It is kept here only so the alias method can be benchmarked against it.
+type legacyNonUniformRandGen struct {
+	cumulative []uint64
+	total      uint64
+}
+
+func newLegacyNonUniformRandGen(weights []uint32) *legacyNonUniformRandGen {
+	o := new(legacyNonUniformRandGen)
+	o.cumulative = make([]uint64, len(weights))
+	var sum uint64
+	for i, w := range weights {
+		sum += uint64(w)
+		o.cumulative[i] = sum
+	}
+	o.total = sum
+	return o
+}
+
+func (o *legacyNonUniformRandGen) Generate() int {
+	target := rand.Uint64() % o.total
+	for i, c := range o.cumulative {
+		if target < c {
+			return i
+		}
+	}
+	return len(o.cumulative) - 1
+}
+
+func benchWeights(n int) []uint32 {
+	weights := make([]uint32, n)
+	for i := range weights {
+		weights[i] = uint32(i%100 + 1)
+	}
+	return weights
+}
+
+func benchmarkAlias(b *testing.B, n int) {
+	gen, err := NewNonUniformRandGen(benchWeights(n))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		gen.Generate()
+	}
+}
+
+func benchmarkLegacy(b *testing.B, n int) {
+	gen := newLegacyNonUniformRandGen(benchWeights(n))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		gen.Generate()
+	}
+}
+
+func BenchmarkNonUniformRandGenAlias10(b *testing.B)   { benchmarkAlias(b, 10) }
+func BenchmarkNonUniformRandGenAlias1k(b *testing.B)   { benchmarkAlias(b, 1000) }
+func BenchmarkNonUniformRandGenAlias100k(b *testing.B) { benchmarkAlias(b, 100000) }
+
+func BenchmarkNonUniformRandGenLegacy10(b *testing.B)   { benchmarkLegacy(b, 10) }
+func BenchmarkNonUniformRandGenLegacy1k(b *testing.B)   { benchmarkLegacy(b, 1000) }
+func BenchmarkNonUniformRandGenLegacy100k(b *testing.B) { benchmarkLegacy(b, 100000) }
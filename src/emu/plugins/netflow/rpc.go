@@ -0,0 +1,470 @@
+// Copyright (c) 2020 Cisco Systems and/or its affiliates.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package netflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"external/osamingo/jsonrpc"
+)
+
+/* ------------------------------------------------------------------------------
+						engineRegistry
+--------------------------------------------------------------------------------*/
+// engineRegistry is a keyed store of field engines, owned by the netflow subsystem,
+// plus the bindings of an engine-id to a (template-id, field-offset) pair in an
+// emitter. It lets field engines be built and rewired at runtime from JSON-RPC,
+// rather than hardcoded in Go, which matters for a long-lived service whose traffic
+// profiles change without a restart.
+type engineRegistry struct {
+	mu      sync.Mutex
+	engines map[string]FieldEngineIF
+	binds   map[string]map[uint16]string // templateID -> fieldOffset -> engineID
+}
+
+// newEngineRegistry creates a new, empty engineRegistry.
+func newEngineRegistry() *engineRegistry {
+	return &engineRegistry{
+		engines: make(map[string]FieldEngineIF),
+		binds:   make(map[string]map[uint16]string),
+	}
+}
+
+// GetEngine returns the engine registered under engineID, if any.
+func (r *engineRegistry) GetEngine(engineID string) (FieldEngineIF, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	engine, ok := r.engines[engineID]
+	return engine, ok
+}
+
+// GetBoundEngine returns the engine bound to fieldOffset in templateID, if any.
+func (r *engineRegistry) GetBoundEngine(templateID string, fieldOffset uint16) (FieldEngineIF, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fields, ok := r.binds[templateID]
+	if !ok {
+		return nil, false
+	}
+	engineID, ok := fields[fieldOffset]
+	if !ok {
+		return nil, false
+	}
+	engine, ok := r.engines[engineID]
+	return engine, ok
+}
+
+/* ------------------------------------------------------------------------------
+						JSON-RPC param/result DTOs
+--------------------------------------------------------------------------------*/
+// UIntEngineDTO is the JSON-RPC representation of UIntEngineParams.
+type UIntEngineDTO struct {
+	Size      uint16 `json:"size"`
+	Offset    uint16 `json:"offset"`
+	Op        string `json:"op"`
+	Step      uint64 `json:"step"`
+	MinValue  uint64 `json:"min_value"`
+	MaxValue  uint64 `json:"max_value"`
+	InitValue uint64 `json:"init_value"`
+}
+
+func (d *UIntEngineDTO) build() (*UIntEngine, error) {
+	return NewUIntEngine(&UIntEngineParams{
+		size:      d.Size,
+		offset:    d.Offset,
+		op:        d.Op,
+		step:      d.Step,
+		minValue:  d.MinValue,
+		maxValue:  d.MaxValue,
+		initValue: d.InitValue,
+	})
+}
+
+// HistogramEntryDTO is the JSON-RPC representation of a HistogramEntry. Kind selects
+// which of the fields below are used to build it.
+type HistogramEntryDTO struct {
+	Kind string `json:"kind"` // one of: uint32, uint32_range, uint32_list, rune, rune_range, rune_list, log_linear, hdr, cdf, nested_engine
+	Prob uint32 `json:"prob"`
+
+	Value uint32   `json:"value,omitempty"` // uint32
+	Min   uint32   `json:"min,omitempty"`   // uint32_range, rune_range
+	Max   uint32   `json:"max,omitempty"`   // uint32_range, rune_range
+	List  []uint32 `json:"list,omitempty"`  // uint32_list
+	Rune  string   `json:"rune,omitempty"`  // rune, single rune
+	Runes string   `json:"runes,omitempty"` // rune_list, one entry per rune in the string
+
+	Size    uint16               `json:"size,omitempty"`     // log_linear, hdr, cdf
+	IsFloat bool                 `json:"is_float,omitempty"` // log_linear, cdf
+	Bins    []LogLinearBinWeight `json:"bins,omitempty"`     // log_linear
+
+	LowestValue  uint64         `json:"lowest_value,omitempty"`  // hdr
+	HighestValue uint64         `json:"highest_value,omitempty"` // hdr
+	SigDigits    uint8          `json:"sig_digits,omitempty"`    // hdr
+	HDRBins      []HDRBinWeight `json:"hdr_bins,omitempty"`      // hdr
+
+	Buckets  []CDFBucket `json:"buckets,omitempty"`   // cdf
+	MaxValue float64     `json:"max_value,omitempty"` // cdf, clamps a +Inf upper bound
+
+	// Nested declares a full sub-engine (typically a template or string engine) whose
+	// generated bytes become this entry's value. It is what lets a HistogramEngine pick
+	// between whole alternative templates, e.g. "80% /api/v1/{...}, 20% /static/{...}".
+	Nested *EngineCreateParams `json:"nested,omitempty"` // nested_engine
+}
+
+func (d *HistogramEntryDTO) build() (HistogramEntry, error) {
+	switch d.Kind {
+	case "uint32":
+		return &HistogramUInt32Entry{v: d.Value, prob: d.Prob}, nil
+	case "uint32_range":
+		return &HistogramUInt32RangeEntry{min: d.Min, max: d.Max, prob: d.Prob}, nil
+	case "uint32_list":
+		return &HistogramUInt32ListEntry{list: d.List, prob: d.Prob}, nil
+	case "rune":
+		runes := []rune(d.Rune)
+		if len(runes) != 1 {
+			return nil, fmt.Errorf("Rune entry must hold exactly one rune, got %q.\n", d.Rune)
+		}
+		return &HistogramRuneEntry{r: runes[0], prob: d.Prob}, nil
+	case "rune_range":
+		return &HistogramRuneRangeEntry{min: rune(d.Min), max: rune(d.Max), prob: d.Prob}, nil
+	case "rune_list":
+		return &HistogramRuneListEntry{list: []rune(d.Runes), prob: d.Prob}, nil
+	case "log_linear":
+		return NewHistogramLogLinearEntry(&HistogramLogLinearEntryParams{
+			prob: d.Prob, size: d.Size, isFloat: d.IsFloat, bins: d.Bins,
+		})
+	case "hdr":
+		return NewHistogramHDREntry(&HistogramHDREntryParams{
+			prob: d.Prob, size: d.Size, lowestValue: d.LowestValue,
+			highestValue: d.HighestValue, sigDigits: d.SigDigits, bins: d.HDRBins,
+		})
+	case "cdf":
+		return NewHistogramCDFEntry(&HistogramCDFEntryParams{
+			prob: d.Prob, size: d.Size, isFloat: d.IsFloat, buckets: d.Buckets, maxValue: d.MaxValue,
+		})
+	case "nested_engine":
+		if d.Nested == nil {
+			return nil, errors.New("Missing \"nested\" payload for histogram entry kind \"nested_engine\".\n")
+		}
+		engine, err := d.Nested.build()
+		if err != nil {
+			return nil, err
+		}
+		return NewTemplateHistogramEntry(engine, d.Prob), nil
+	default:
+		return nil, fmt.Errorf("Unsupported histogram entry kind %q.\n", d.Kind)
+	}
+}
+
+// HistogramEngineDTO is the JSON-RPC representation of HistogramEngineParams.
+type HistogramEngineDTO struct {
+	Size    uint16              `json:"size"` // must be at least the largest entry's worst case size, e.g. the biggest nested_engine GetSize()
+	Offset  uint16              `json:"offset"`
+	Entries []HistogramEntryDTO `json:"entries"`
+}
+
+func (d *HistogramEngineDTO) build() (*HistogramEngine, error) {
+	entries := make([]HistogramEntry, 0, len(d.Entries))
+	for i := range d.Entries {
+		entry, err := d.Entries[i].build()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return NewHistogramEngine(&HistogramEngineParams{size: d.Size, offset: d.Offset, entries: entries})
+}
+
+// StringEngineDTO is the JSON-RPC representation of StringEngineParams.
+type StringEngineDTO struct {
+	Offset  uint16              `json:"offset"`
+	MinLen  uint16              `json:"min_len"`
+	MaxLen  uint16              `json:"max_len"`
+	Entries []HistogramEntryDTO `json:"entries"`
+}
+
+func (d *StringEngineDTO) build() (*StringEngine, error) {
+	entries := make([]HistogramEntry, 0, len(d.Entries))
+	for i := range d.Entries {
+		entry, err := d.Entries[i].build()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return NewStringEngine(&StringEngineParams{offset: d.Offset, minLen: d.MinLen, maxLen: d.MaxLen, entries: entries})
+}
+
+// TemplateElementDTO is the JSON-RPC representation of a TemplateElement: either a
+// literal, or a nested engine declaration. Exactly one of Literal or Engine should be set.
+type TemplateElementDTO struct {
+	Literal []byte              `json:"literal,omitempty"`
+	Engine  *EngineCreateParams `json:"engine,omitempty"`
+}
+
+func (d *TemplateElementDTO) build() (TemplateElement, error) {
+	if d.Engine == nil {
+		return TemplateElement{Literal: d.Literal}, nil
+	}
+	engine, err := d.Engine.build()
+	if err != nil {
+		return TemplateElement{}, err
+	}
+	return TemplateElement{Engine: engine}, nil
+}
+
+// TemplateEngineDTO is the JSON-RPC representation of TemplateEngineParams.
+type TemplateEngineDTO struct {
+	Offset   uint16               `json:"offset"`
+	Elements []TemplateElementDTO `json:"elements"`
+}
+
+func (d *TemplateEngineDTO) build() (*TemplateEngine, error) {
+	elements := make([]TemplateElement, 0, len(d.Elements))
+	for i := range d.Elements {
+		element, err := d.Elements[i].build()
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+	}
+	return NewTemplateEngine(&TemplateEngineParams{offset: d.Offset, elements: elements})
+}
+
+// EngineCreateParams is the JSON-RPC params for netflow_engine_create and
+// netflow_engine_update_params. Kind selects which of the typed payloads is used.
+type EngineCreateParams struct {
+	EngineID  string              `json:"engine_id"`
+	Kind      string              `json:"kind"` // one of: uint, histogram, string, template
+	UInt      *UIntEngineDTO      `json:"uint,omitempty"`
+	Histogram *HistogramEngineDTO `json:"histogram,omitempty"`
+	String    *StringEngineDTO    `json:"string,omitempty"`
+	Template  *TemplateEngineDTO  `json:"template,omitempty"`
+}
+
+func (p *EngineCreateParams) build() (FieldEngineIF, error) {
+	switch p.Kind {
+	case "uint":
+		if p.UInt == nil {
+			return nil, errors.New("Missing \"uint\" payload for engine kind \"uint\".\n")
+		}
+		return p.UInt.build()
+	case "histogram":
+		if p.Histogram == nil {
+			return nil, errors.New("Missing \"histogram\" payload for engine kind \"histogram\".\n")
+		}
+		return p.Histogram.build()
+	case "string":
+		if p.String == nil {
+			return nil, errors.New("Missing \"string\" payload for engine kind \"string\".\n")
+		}
+		return p.String.build()
+	case "template":
+		if p.Template == nil {
+			return nil, errors.New("Missing \"template\" payload for engine kind \"template\".\n")
+		}
+		return p.Template.build()
+	default:
+		return nil, fmt.Errorf("Unsupported engine kind %q.\n", p.Kind)
+	}
+}
+
+// EngineIDResult is the JSON-RPC result for methods that return a single engine-id.
+type EngineIDResult struct {
+	EngineID string `json:"engine_id"`
+}
+
+// EngineListResult is the JSON-RPC result for netflow_engine_list.
+type EngineListResult struct {
+	EngineIDs []string `json:"engine_ids"`
+}
+
+// EngineDeleteParams is the JSON-RPC params for netflow_engine_delete.
+type EngineDeleteParams struct {
+	EngineID string `json:"engine_id"`
+}
+
+// EngineBindParams is the JSON-RPC params for netflow_engine_bind.
+type EngineBindParams struct {
+	EngineID    string `json:"engine_id"`
+	TemplateID  string `json:"template_id"`
+	FieldOffset uint16 `json:"field_offset"`
+}
+
+// EngineBindResult is the JSON-RPC result for netflow_engine_bind.
+type EngineBindResult struct {
+	Bound bool `json:"bound"`
+}
+
+/* ------------------------------------------------------------------------------
+						JSON-RPC handlers
+--------------------------------------------------------------------------------*/
+// decodeParams unmarshals raw into v, reporting a standard invalid params error on failure.
+func decodeParams(raw *json.RawMessage, v interface{}) *jsonrpc.Error {
+	if raw == nil {
+		return jsonrpc.ErrInvalidParams()
+	}
+	if err := json.Unmarshal(*raw, v); err != nil {
+		return jsonrpc.ErrInvalidParams()
+	}
+	return nil
+}
+
+// invalidParamsErr wraps err as a JSON-RPC invalid params error, keeping err's message.
+func invalidParamsErr(err error) *jsonrpc.Error {
+	jerr := jsonrpc.ErrInvalidParams()
+	jerr.Data = err.Error()
+	return jerr
+}
+
+// FieldEngineAPI implements the netflow_engine_* JSON-RPC methods on top of an engineRegistry.
+type FieldEngineAPI struct {
+	registry *engineRegistry
+}
+
+// NewFieldEngineAPI creates a new FieldEngineAPI with an empty engine registry.
+func NewFieldEngineAPI() *FieldEngineAPI {
+	return &FieldEngineAPI{registry: newEngineRegistry()}
+}
+
+// RegisterFieldEngineMethods registers the engine lifecycle JSON-RPC methods onto mr.
+func RegisterFieldEngineMethods(mr *jsonrpc.MethodRepository, api *FieldEngineAPI) error {
+	methods := map[string]jsonrpc.Handler{
+		"netflow_engine_create":        engineCreateHandler{api},
+		"netflow_engine_update_params": engineUpdateParamsHandler{api},
+		"netflow_engine_list":          engineListHandler{api},
+		"netflow_engine_delete":        engineDeleteHandler{api},
+		"netflow_engine_bind":          engineBindHandler{api},
+	}
+	for name, h := range methods {
+		if err := mr.RegisterMethod(name, h, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type engineCreateHandler struct{ api *FieldEngineAPI }
+
+// ServeJSONRPC implements netflow_engine_create: builds a new field engine from its
+// declarative JSON description and stores it in the registry under engine_id.
+func (h engineCreateHandler) ServeJSONRPC(c context.Context, params *json.RawMessage) (interface{}, *jsonrpc.Error) {
+	var p EngineCreateParams
+	if jerr := decodeParams(params, &p); jerr != nil {
+		return nil, jerr
+	}
+	if p.EngineID == "" {
+		return nil, jsonrpc.ErrInvalidParams()
+	}
+	r := h.api.registry
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.engines[p.EngineID]; exists {
+		return nil, invalidParamsErr(fmt.Errorf("Engine %q already exists.\n", p.EngineID))
+	}
+	engine, err := p.build()
+	if err != nil {
+		return nil, invalidParamsErr(err)
+	}
+	r.engines[p.EngineID] = engine
+	return &EngineIDResult{EngineID: p.EngineID}, nil
+}
+
+type engineUpdateParamsHandler struct{ api *FieldEngineAPI }
+
+// ServeJSONRPC implements netflow_engine_update_params: rebuilds an existing engine in
+// place from a new declarative JSON description, without touching its bindings.
+func (h engineUpdateParamsHandler) ServeJSONRPC(c context.Context, params *json.RawMessage) (interface{}, *jsonrpc.Error) {
+	var p EngineCreateParams
+	if jerr := decodeParams(params, &p); jerr != nil {
+		return nil, jerr
+	}
+	r := h.api.registry
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.engines[p.EngineID]; !exists {
+		return nil, invalidParamsErr(fmt.Errorf("Engine %q does not exist.\n", p.EngineID))
+	}
+	engine, err := p.build()
+	if err != nil {
+		return nil, invalidParamsErr(err)
+	}
+	r.engines[p.EngineID] = engine
+	return &EngineIDResult{EngineID: p.EngineID}, nil
+}
+
+type engineListHandler struct{ api *FieldEngineAPI }
+
+// ServeJSONRPC implements netflow_engine_list: returns every registered engine-id.
+func (h engineListHandler) ServeJSONRPC(c context.Context, params *json.RawMessage) (interface{}, *jsonrpc.Error) {
+	r := h.api.registry
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]string, 0, len(r.engines))
+	for id := range r.engines {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return &EngineListResult{EngineIDs: ids}, nil
+}
+
+type engineDeleteHandler struct{ api *FieldEngineAPI }
+
+// ServeJSONRPC implements netflow_engine_delete: removes an engine and any binding
+// that referenced it.
+func (h engineDeleteHandler) ServeJSONRPC(c context.Context, params *json.RawMessage) (interface{}, *jsonrpc.Error) {
+	var p EngineDeleteParams
+	if jerr := decodeParams(params, &p); jerr != nil {
+		return nil, jerr
+	}
+	r := h.api.registry
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.engines[p.EngineID]; !exists {
+		return nil, invalidParamsErr(fmt.Errorf("Engine %q does not exist.\n", p.EngineID))
+	}
+	delete(r.engines, p.EngineID)
+	for _, fields := range r.binds {
+		for offset, engineID := range fields {
+			if engineID == p.EngineID {
+				delete(fields, offset)
+			}
+		}
+	}
+	return &EngineIDResult{EngineID: p.EngineID}, nil
+}
+
+type engineBindHandler struct{ api *FieldEngineAPI }
+
+// ServeJSONRPC implements netflow_engine_bind: attaches an engine-id to a
+// (template-id, field-offset) pair in an emitter.
+func (h engineBindHandler) ServeJSONRPC(c context.Context, params *json.RawMessage) (interface{}, *jsonrpc.Error) {
+	var p EngineBindParams
+	if jerr := decodeParams(params, &p); jerr != nil {
+		return nil, jerr
+	}
+	if p.TemplateID == "" {
+		return nil, jsonrpc.ErrInvalidParams()
+	}
+	r := h.api.registry
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.engines[p.EngineID]; !exists {
+		return nil, invalidParamsErr(fmt.Errorf("Engine %q does not exist.\n", p.EngineID))
+	}
+	fields, ok := r.binds[p.TemplateID]
+	if !ok {
+		fields = make(map[uint16]string)
+		r.binds[p.TemplateID] = fields
+	}
+	fields[p.FieldOffset] = p.EngineID
+	return &EngineBindResult{Bound: true}, nil
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2020 Cisco Systems and/or its affiliates.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package netflow
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func rawMessage(t *testing.T, v interface{}) *json.RawMessage {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %v", err)
+	}
+	raw := json.RawMessage(b)
+	return &raw
+}
+
+func TestEngineCreateAndBindRoundTrip(t *testing.T) {
+	api := NewFieldEngineAPI()
+
+	createParams := rawMessage(t, EngineCreateParams{
+		EngineID: "eng1",
+		Kind:     "uint",
+		UInt: &UIntEngineDTO{
+			Size: 4, Op: "rand", MinValue: 0, MaxValue: 10,
+		},
+	})
+	result, jerr := (engineCreateHandler{api}).ServeJSONRPC(context.Background(), createParams)
+	if jerr != nil {
+		t.Fatalf("netflow_engine_create failed: %v", jerr)
+	}
+	created, ok := result.(*EngineIDResult)
+	if !ok || created.EngineID != "eng1" {
+		t.Fatalf("netflow_engine_create returned unexpected result: %#v", result)
+	}
+	if _, ok := api.registry.GetEngine("eng1"); !ok {
+		t.Fatal("engine eng1 was not stored in the registry")
+	}
+
+	bindParams := rawMessage(t, EngineBindParams{EngineID: "eng1", TemplateID: "tmpl1", FieldOffset: 4})
+	result, jerr = (engineBindHandler{api}).ServeJSONRPC(context.Background(), bindParams)
+	if jerr != nil {
+		t.Fatalf("netflow_engine_bind failed: %v", jerr)
+	}
+	if bound, ok := result.(*EngineBindResult); !ok || !bound.Bound {
+		t.Fatalf("netflow_engine_bind returned unexpected result: %#v", result)
+	}
+	if _, ok := api.registry.GetBoundEngine("tmpl1", 4); !ok {
+		t.Fatal("engine eng1 was not bound to (tmpl1, 4)")
+	}
+
+	listResult, jerr := (engineListHandler{api}).ServeJSONRPC(context.Background(), nil)
+	if jerr != nil {
+		t.Fatalf("netflow_engine_list failed: %v", jerr)
+	}
+	if ids := listResult.(*EngineListResult).EngineIDs; len(ids) != 1 || ids[0] != "eng1" {
+		t.Fatalf("netflow_engine_list returned %v, want [eng1]", ids)
+	}
+
+	deleteParams := rawMessage(t, EngineDeleteParams{EngineID: "eng1"})
+	if _, jerr := (engineDeleteHandler{api}).ServeJSONRPC(context.Background(), deleteParams); jerr != nil {
+		t.Fatalf("netflow_engine_delete failed: %v", jerr)
+	}
+	if _, ok := api.registry.GetEngine("eng1"); ok {
+		t.Fatal("engine eng1 should have been deleted")
+	}
+	if _, ok := api.registry.GetBoundEngine("tmpl1", 4); ok {
+		t.Fatal("binding to eng1 should have been dropped along with the engine")
+	}
+}
+
+func TestEngineBindRejectsUnknownEngine(t *testing.T) {
+	api := NewFieldEngineAPI()
+	bindParams := rawMessage(t, EngineBindParams{EngineID: "missing", TemplateID: "tmpl1", FieldOffset: 0})
+	if _, jerr := (engineBindHandler{api}).ServeJSONRPC(context.Background(), bindParams); jerr == nil {
+		t.Fatal("expected an error when binding a nonexistent engine")
+	}
+}
+
+// buildEngine is a test helper that drives engine creation the same way
+// netflow_engine_create does, without going through the registry.
+func buildEngine(t *testing.T, params EngineCreateParams) FieldEngineIF {
+	t.Helper()
+	engine, err := (&params).build()
+	if err != nil {
+		t.Fatalf("building engine kind %q failed: %v", params.Kind, err)
+	}
+	return engine
+}
+
+func TestEngineCreateHistogramKindRoundTrip(t *testing.T) {
+	createParams := rawMessage(t, EngineCreateParams{
+		EngineID: "hist1",
+		Kind:     "histogram",
+		Histogram: &HistogramEngineDTO{
+			Size: 4,
+			Entries: []HistogramEntryDTO{
+				{Kind: "uint32", Value: 1, Prob: 1},
+				{Kind: "uint32", Value: 2, Prob: 1},
+			},
+		},
+	})
+	api := NewFieldEngineAPI()
+	result, jerr := (engineCreateHandler{api}).ServeJSONRPC(context.Background(), createParams)
+	if jerr != nil {
+		t.Fatalf("netflow_engine_create failed: %v", jerr)
+	}
+	if result.(*EngineIDResult).EngineID != "hist1" {
+		t.Fatalf("netflow_engine_create returned unexpected result: %#v", result)
+	}
+	engine, ok := api.registry.GetEngine("hist1")
+	if !ok {
+		t.Fatal("engine hist1 was not stored in the registry")
+	}
+	b := make([]byte, engine.GetSize())
+	if err := engine.Update(b); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+}
+
+func TestEngineCreateStringKindRoundTrip(t *testing.T) {
+	engine := buildEngine(t, EngineCreateParams{
+		Kind: "string",
+		String: &StringEngineDTO{
+			MinLen: 1, MaxLen: 5,
+			Entries: []HistogramEntryDTO{{Kind: "rune", Rune: "a", Prob: 1}},
+		},
+	})
+	b := make([]byte, engine.GetSize())
+	if err := engine.Update(b); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+}
+
+func TestEngineCreateTemplateWithNestedHistogramRoundTrip(t *testing.T) {
+	// Mirrors the "80% /api/v1/{...}, 20% /static/{...}" example: a HistogramEngine
+	// nested inside a TemplateEngine, picking between two alternative sub-templates of
+	// different actual lengths.
+	params := EngineCreateParams{
+		Kind: "template",
+		Template: &TemplateEngineDTO{
+			Elements: []TemplateElementDTO{
+				{Engine: &EngineCreateParams{
+					Kind: "histogram",
+					Histogram: &HistogramEngineDTO{
+						Size: 48, // must cover the largest nested alternative's worst case size, 12 runes * 4 bytes
+						Entries: []HistogramEntryDTO{
+							{
+								Kind: "nested_engine",
+								Prob: 80,
+								Nested: &EngineCreateParams{
+									Kind: "string",
+									String: &StringEngineDTO{
+										MinLen: 3, MaxLen: 12,
+										Entries: []HistogramEntryDTO{{Kind: "rune", Rune: "a", Prob: 1}},
+									},
+								},
+							},
+							{
+								Kind: "nested_engine",
+								Prob: 20,
+								Nested: &EngineCreateParams{
+									Kind: "string",
+									String: &StringEngineDTO{
+										MinLen: 1, MaxLen: 1,
+										Entries: []HistogramEntryDTO{{Kind: "rune", Rune: "b", Prob: 1}},
+									},
+								},
+							},
+						},
+					},
+				}},
+			},
+		},
+	}
+	engine := buildEngine(t, params)
+	b := make([]byte, engine.GetSize())
+	for i := 0; i < 100; i++ {
+		if err := engine.Update(b); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright (c) 2020 Cisco Systems and/or its affiliates.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package netflow
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// variableSizeEngine is implemented by field engines whose Update doesn't always
+// consume the full GetSize() bytes it was handed. TemplateEngine uses it to learn
+// how many bytes a sub-engine actually wrote, without growing the FieldEngineIF
+// contract that every other engine relies on.
+type variableSizeEngine interface {
+	FieldEngineIF
+	// LastWriteSize returns the number of bytes the last call to Update actually wrote.
+	LastWriteSize() uint16
+}
+
+/* ------------------------------------------------------------------------------
+							StringEngine
+--------------------------------------------------------------------------------*/
+// StringEngineParams is a struct of parameters for the StringEngine.
+type StringEngineParams struct {
+	offset  uint16           // offset in which to write in the packet
+	minLen  uint16           // minimal string length, in runes
+	maxLen  uint16           // maximal string length, in runes
+	entries []HistogramEntry // distribution of runes to draw from, typically HistogramRune*Entry
+}
+
+// StringEngine is a field engine which generates length-bounded UTF-8 strings by drawing
+// runes, one at a time, from a HistogramRune*Entry distribution, until a random length in
+// [minLen, maxLen] runes has been produced. It is meant to be used on its own or nested
+// inside a TemplateEngine to compose realistic variable-length NetFlow v9/IPFIX fields
+// such as DNS qnames, HTTP user-agents or URL paths.
+type StringEngine struct {
+	par       *StringEngineParams
+	generator *NonUniformRandGen
+	lastSize  uint16 // size in bytes actually written on the last call to Update
+}
+
+// NewStringEngine creates a new StringEngine from the StringEngineParams provided.
+func NewStringEngine(params *StringEngineParams) (o *StringEngine, err error) {
+	if params.minLen > params.maxLen {
+		return nil, fmt.Errorf("Min length %v is bigger than max length %v.\n", params.minLen, params.maxLen)
+	}
+	// GetSize reports maxLen runes at 4 bytes each (the widest possible UTF-8 encoding),
+	// and that worst case has to fit in the uint16 FieldEngineIF.GetSize contract.
+	if params.maxLen > math.MaxUint16/4 {
+		return nil, fmt.Errorf("Max length %v is too big, maxLen * 4 must fit in a uint16.\n", params.maxLen)
+	}
+	if len(params.entries) == 0 {
+		return nil, errors.New("Can't create a StringEngine with an empty list of rune entries.\n")
+	}
+	o = new(StringEngine)
+	o.par = params
+	distribution := make([]uint32, len(params.entries))
+	for i, entry := range params.entries {
+		distribution[i] = entry.GetProb()
+	}
+	o.generator, err = NewNonUniformRandGen(distribution)
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Update implements the Update function of FieldEngineIF. It draws a random length in
+// [minLen, maxLen] runes and, for each rune, samples the configured distribution,
+// appending the generated bytes to b. Call LastWriteSize afterwards to find out how
+// many of the GetSize() bytes it was handed were actually used.
+func (o *StringEngine) Update(b []byte) error {
+	if len(b) < int(o.GetSize()) {
+		return fmt.Errorf("Provided slice is shorter that the size of the variable to write, want at least %v, have %v.\n", o.GetSize(), len(b))
+	}
+	numRunes := o.par.minLen
+	if o.par.maxLen > o.par.minLen {
+		numRunes += uint16(rand.Intn(int(o.par.maxLen-o.par.minLen) + 1))
+	}
+	pos := 0
+	for i := uint16(0); i < numRunes; i++ {
+		entry := o.par.entries[o.generator.Generate()]
+		valueBytes, err := entry.GetValue()
+		if err != nil {
+			return err
+		}
+		pos += copy(b[pos:], valueBytes)
+	}
+	o.lastSize = uint16(pos)
+	return nil
+}
+
+// GetOffset implements the GetOffset function of FieldEngineIF.
+func (o *StringEngine) GetOffset() uint16 {
+	return o.par.offset
+}
+
+// GetSize implements the GetSize function of FieldEngineIF. It returns the worst case
+// size, assuming maxLen runes are drawn and every one of them is the widest possible
+// UTF-8 encoded rune (4 bytes). The actual number of bytes written by Update is usually
+// smaller, and can be read back with LastWriteSize.
+func (o *StringEngine) GetSize() uint16 {
+	return o.par.maxLen * 4
+}
+
+// LastWriteSize implements the variableSizeEngine interface.
+func (o *StringEngine) LastWriteSize() uint16 {
+	return o.lastSize
+}
+
+/* ------------------------------------------------------------------------------
+							TemplateEngine
+--------------------------------------------------------------------------------*/
+// TemplateElement is a single piece of a TemplateEngine. It is either a literal byte
+// slice that is emitted as-is, or a sub-engine whose output is emitted in its place.
+// Exactly one of Literal or Engine should be set.
+type TemplateElement struct {
+	Literal []byte        // fixed bytes to emit for this element, used when Engine is nil
+	Engine  FieldEngineIF // sub-engine that generates this element's bytes, e.g. a UIntEngine, HistogramEngine or StringEngine
+}
+
+// TemplateEngineParams is a struct of parameters for the TemplateEngine.
+type TemplateEngineParams struct {
+	offset   uint16            // offset in which to write in the packet
+	elements []TemplateElement // ordered sequence of literals and sub-engines to compose
+}
+
+// TemplateEngine is a field engine which composes its output from a sequence of
+// sub-engines (any mix of UIntEngine, HistogramEngine, StringEngine and literal bytes).
+// It lets users declare per-field mini-grammars, e.g. "80% /api/v1/{3-12 lowercase
+// letters}, 20% /static/{...}" by nesting a HistogramEngine of TemplateHistogramEntry
+// alternatives as one of its elements, driving realistic NetFlow v9/IPFIX variable
+// length information elements.
+type TemplateEngine struct {
+	par      *TemplateEngineParams
+	maxSize  uint16 // sum of every element's max size, cached at construction
+	lastSize uint16 // size in bytes actually written on the last call to Update
+}
+
+// NewTemplateEngine creates a new TemplateEngine from the TemplateEngineParams provided.
+func NewTemplateEngine(params *TemplateEngineParams) (o *TemplateEngine, err error) {
+	if len(params.elements) == 0 {
+		return nil, errors.New("Can't create a TemplateEngine with an empty list of elements.\n")
+	}
+	o = new(TemplateEngine)
+	o.par = params
+	var maxSize uint32
+	for _, el := range params.elements {
+		if el.Engine == nil {
+			maxSize += uint32(len(el.Literal))
+		} else {
+			maxSize += uint32(el.Engine.GetSize())
+		}
+	}
+	if maxSize > math.MaxUint16 {
+		return nil, fmt.Errorf("Total template size %v is too big, it must fit in a uint16.\n", maxSize)
+	}
+	o.maxSize = uint16(maxSize)
+	return o, nil
+}
+
+// Update implements the Update function of FieldEngineIF. It writes every element in
+// order into b: literals are copied verbatim, sub-engines are asked to Update the next
+// slice of b and, if they report a variable write size, only their actual bytes are
+// kept and the following elements are shifted back accordingly.
+func (o *TemplateEngine) Update(b []byte) error {
+	if len(b) < int(o.maxSize) {
+		return fmt.Errorf("Provided slice is shorter that the size of the variable to write, want at least %v, have %v.\n", o.maxSize, len(b))
+	}
+	pos := 0
+	for _, el := range o.par.elements {
+		if el.Engine == nil {
+			pos += copy(b[pos:], el.Literal)
+			continue
+		}
+		size := el.Engine.GetSize()
+		if err := el.Engine.Update(b[pos : pos+int(size)]); err != nil {
+			return err
+		}
+		written := size
+		if vse, ok := el.Engine.(variableSizeEngine); ok {
+			written = vse.LastWriteSize()
+		}
+		pos += int(written)
+	}
+	o.lastSize = uint16(pos)
+	return nil
+}
+
+// GetOffset implements the GetOffset function of FieldEngineIF.
+func (o *TemplateEngine) GetOffset() uint16 {
+	return o.par.offset
+}
+
+// GetSize implements the GetSize function of FieldEngineIF. It returns the worst case
+// size, the sum of every element's max size. The actual number of bytes written by
+// Update is usually smaller when it contains variable length elements, and can be read
+// back with LastWriteSize.
+func (o *TemplateEngine) GetSize() uint16 {
+	return o.maxSize
+}
+
+// LastWriteSize implements the variableSizeEngine interface, allowing a TemplateEngine
+// to be nested inside another TemplateEngine.
+func (o *TemplateEngine) LastWriteSize() uint16 {
+	return o.lastSize
+}
+
+/* ------------------------------------------------------------------------------
+						TemplateHistogramEntry
+--------------------------------------------------------------------------------*/
+// TemplateHistogramEntry adapts a FieldEngineIF (typically a TemplateEngine or
+// StringEngine) into a HistogramEntry, so that it can be used as one alternative of a
+// HistogramEngine. This is what lets a HistogramEngine be nested as a sub-engine inside
+// a TemplateEngine: each alternative is itself a full template, chosen with probability
+// prob, e.g. "80% /api/v1/{...}, 20% /static/{...}".
+type TemplateHistogramEntry struct {
+	engine FieldEngineIF // generates the value for this entry when it is picked
+	prob   uint32        // probability of this entry
+}
+
+// NewTemplateHistogramEntry creates a new TemplateHistogramEntry wrapping engine.
+func NewTemplateHistogramEntry(engine FieldEngineIF, prob uint32) *TemplateHistogramEntry {
+	return &TemplateHistogramEntry{engine: engine, prob: prob}
+}
+
+// GetValue runs the wrapped engine and puts its generated bytes on the byte buffer,
+// trimmed to its actual write size when the wrapped engine is of variable length.
+func (o *TemplateHistogramEntry) GetValue() (b []byte, err error) {
+	buf := make([]byte, o.engine.GetSize())
+	if err = o.engine.Update(buf); err != nil {
+		return nil, err
+	}
+	if vse, ok := o.engine.(variableSizeEngine); ok {
+		return buf[:vse.LastWriteSize()], nil
+	}
+	return buf, nil
+}
+
+// GetProb returns the probability for this entry to be picked in the histogram engine.
+func (o *TemplateHistogramEntry) GetProb() uint32 {
+	return o.prob
+}
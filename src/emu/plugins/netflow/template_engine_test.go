@@ -0,0 +1,56 @@
+// Copyright (c) 2020 Cisco Systems and/or its affiliates.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package netflow
+
+import "testing"
+
+func TestNewStringEngineRejectsOversizedMaxLen(t *testing.T) {
+	_, err := NewStringEngine(&StringEngineParams{
+		minLen: 1, maxLen: 20000, // 20000 * 4 overflows uint16
+		entries: []HistogramEntry{&HistogramRuneEntry{r: 'a', prob: 1}},
+	})
+	if err == nil {
+		t.Fatal("expected an error when maxLen * 4 overflows a uint16")
+	}
+}
+
+func TestNewTemplateEngineRejectsOversizedTotal(t *testing.T) {
+	big, err := NewUIntEngine(&UIntEngineParams{size: 8, op: "rand", minValue: 0, maxValue: 1})
+	if err != nil {
+		t.Fatalf("NewUIntEngine failed: %v", err)
+	}
+	elements := make([]TemplateElement, 0, 10000)
+	for i := 0; i < 10000; i++ {
+		elements = append(elements, TemplateElement{Engine: big})
+	}
+	if _, err := NewTemplateEngine(&TemplateEngineParams{elements: elements}); err == nil {
+		t.Fatal("expected an error when the summed element sizes overflow a uint16")
+	}
+}
+
+func TestTemplateEngineUpdateConcatenatesElements(t *testing.T) {
+	literal := TemplateElement{Literal: []byte("/static/")}
+	uintEngine, err := NewUIntEngine(&UIntEngineParams{size: 1, op: "rand", minValue: 0, maxValue: 0})
+	if err != nil {
+		t.Fatalf("NewUIntEngine failed: %v", err)
+	}
+	sub := TemplateElement{Engine: uintEngine}
+	tmpl, err := NewTemplateEngine(&TemplateEngineParams{elements: []TemplateElement{literal, sub}})
+	if err != nil {
+		t.Fatalf("NewTemplateEngine failed: %v", err)
+	}
+	b := make([]byte, tmpl.GetSize())
+	if err := tmpl.Update(b); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	want := append([]byte("/static/"), 0)
+	if string(b) != string(want) {
+		t.Errorf("Update produced %q, want %q", b, want)
+	}
+	if tmpl.LastWriteSize() != uint16(len(want)) {
+		t.Errorf("LastWriteSize() = %v, want %v", tmpl.LastWriteSize(), len(want))
+	}
+}